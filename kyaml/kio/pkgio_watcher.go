@@ -0,0 +1,291 @@
+// Copyright 2019 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package kio
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+// DefaultWatchDebounce is the delay Watcher waits after the last observed
+// filesystem event before re-reading the package, so that a burst of
+// related changes (e.g. a save that touches several files) is collapsed
+// into a single re-read.
+const DefaultWatchDebounce = 100 * time.Millisecond
+
+// Watcher watches a local package directory and re-emits the full,
+// parsed set of Resources whenever a file under the package changes.
+//
+// Watcher re-reads the package rather than diffing individual files so
+// that consumers always observe a consistent snapshot -- the same
+// guarantee LocalPackageReader.Read provides for a one-shot read.
+type Watcher struct {
+	// PackagePath is the path to the package directory to watch.
+	PackagePath string
+
+	// PackageFileName is the name of the file identifying a subpackage.
+	// See LocalPackageReader.PackageFileName.
+	PackageFileName string
+
+	// MatchFilesGlob configures which files are read, and which file
+	// events trigger a re-read.  See LocalPackageReader.MatchFilesGlob.
+	MatchFilesGlob []string
+
+	// IncludeSubpackages will configure the Watcher to watch and read
+	// Resources from subpackages.  See LocalPackageReader.IncludeSubpackages.
+	IncludeSubpackages bool
+
+	// ErrorIfNonResources will configure the Watcher to throw an error if
+	// yaml missing apiVersion or kind is read.
+	ErrorIfNonResources bool
+
+	// SetAnnotations are annotations to set on the Resources as they are read.
+	SetAnnotations map[string]string
+
+	// Debounce is how long Watch waits after the most recent filesystem
+	// event before re-reading the package.  Defaults to DefaultWatchDebounce.
+	Debounce time.Duration
+
+	// EmitInitialSnapshot, if set, causes Watch to push the current
+	// contents of the package on the returned channel before waiting for
+	// any filesystem events.
+	EmitInitialSnapshot bool
+}
+
+// Watch starts watching PackagePath and returns a channel of the
+// re-parsed Resources for the package each time it changes, and a
+// channel of errors encountered while watching or re-reading.
+//
+// Both channels are closed once ctx is canceled or the underlying
+// fsnotify watcher is closed.  Errors from the filesystem watcher or
+// from re-parsing the package are sent on the error channel without
+// stopping the watch.  An error while setting up the watch (creating
+// the fsnotify watcher or adding the initial directory watches) is sent
+// on the error channel and then both channels are closed.
+func (r Watcher) Watch(ctx context.Context) (<-chan []*yaml.RNode, <-chan error) {
+	nodesCh := make(chan []*yaml.RNode)
+	errCh := make(chan error)
+
+	go r.run(ctx, nodesCh, errCh)
+
+	return nodesCh, errCh
+}
+
+func (r Watcher) run(ctx context.Context, nodesCh chan<- []*yaml.RNode, errCh chan<- error) {
+	defer close(nodesCh)
+	defer close(errCh)
+
+	if r.Debounce <= 0 {
+		r.Debounce = DefaultWatchDebounce
+	}
+
+	w, err := fsnotify.NewWatcher()
+	if err != nil {
+		r.sendErr(ctx, errCh, err)
+		return
+	}
+	defer w.Close()
+
+	watched := map[string]bool{}
+	if err := r.addWatches(w, watched); err != nil {
+		r.sendErr(ctx, errCh, err)
+		return
+	}
+
+	if r.EmitInitialSnapshot {
+		if !r.emit(ctx, nodesCh, errCh) {
+			return
+		}
+	}
+
+	var timerC <-chan time.Time
+	for {
+		select {
+		case <-ctx.Done():
+			return
+
+		case event, ok := <-w.Events:
+			if !ok {
+				return
+			}
+			// a new directory may be the start of a new subpackage --
+			// start watching it (and anything nested under it) so its
+			// Resources are picked up on the next re-read.  This has to
+			// happen before shouldSkipEvent, which always skips directory
+			// events, or the new watch is never added.
+			if event.Op&fsnotify.Create != 0 {
+				if info, statErr := os.Stat(event.Name); statErr == nil && info.IsDir() {
+					if addErr := r.addWatches(w, watched); addErr != nil {
+						r.sendErr(ctx, errCh, addErr)
+					}
+					timerC = time.After(r.Debounce)
+					continue
+				}
+			}
+			if r.shouldSkipEvent(event) {
+				continue
+			}
+			timerC = time.After(r.Debounce)
+
+		case werr, ok := <-w.Errors:
+			if !ok {
+				return
+			}
+			r.sendErr(ctx, errCh, werr)
+
+		case <-timerC:
+			timerC = nil
+			if !r.emit(ctx, nodesCh, errCh) {
+				return
+			}
+		}
+	}
+}
+
+// reader builds the LocalPackageReader used to produce snapshots of the
+// watched package.
+func (r Watcher) reader() LocalPackageReader {
+	globs := r.MatchFilesGlob
+	if len(globs) == 0 {
+		// mirror LocalPackageReader.Read's default so event matching and
+		// directory watching agree with what's actually read.
+		globs = defaultMatch
+	}
+	return LocalPackageReader{
+		PackagePath:         r.PackagePath,
+		PackageFileName:     r.PackageFileName,
+		MatchFilesGlob:      globs,
+		IncludeSubpackages:  r.IncludeSubpackages,
+		ErrorIfNonResources: r.ErrorIfNonResources,
+		SetAnnotations:      r.SetAnnotations,
+	}
+}
+
+// addWatches walks the package adding a watch for every directory that
+// isn't already tracked, skipping subpackage directories the same way
+// LocalPackageReader.Read does.
+func (r Watcher) addWatches(w *fsnotify.Watcher, watched map[string]bool) error {
+	reader := r.reader()
+	root := filepath.Clean(reader.PackagePath)
+	return filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			// the directory may have been removed between the event firing
+			// and the walk -- nothing more to watch there.
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		if !info.IsDir() {
+			return nil
+		}
+		if path != root {
+			if skipErr := reader.shouldSkipDir(path, info); skipErr != nil {
+				return skipErr
+			}
+		}
+		if watched[path] {
+			return nil
+		}
+		if err := w.Add(path); err != nil {
+			return err
+		}
+		watched[path] = true
+		return nil
+	})
+}
+
+// emit re-reads the package and pushes the result (or any read error) to
+// the caller.  It returns false if ctx was canceled while sending.
+func (r Watcher) emit(ctx context.Context, nodesCh chan<- []*yaml.RNode, errCh chan<- error) bool {
+	nodes, err := r.reader().Read()
+	if err != nil {
+		return r.sendErr(ctx, errCh, err)
+	}
+	select {
+	case nodesCh <- nodes:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// sendErr sends err on errCh, returning false if ctx was canceled first.
+func (r Watcher) sendErr(ctx context.Context, errCh chan<- error, err error) bool {
+	select {
+	case errCh <- err:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// shouldSkipEvent returns true for events that shouldn't trigger a
+// re-read, namely transient files editors create while saving (swap
+// files, lock files, atomic-write temp files) and files that don't
+// match MatchFilesGlob.
+func (r Watcher) shouldSkipEvent(event fsnotify.Event) bool {
+	base := filepath.Base(event.Name)
+	if isTransientFile(base) {
+		return true
+	}
+
+	info, statErr := os.Stat(event.Name)
+	if statErr != nil {
+		// the file is gone (e.g. a Remove/Rename event) -- fall back to
+		// matching on name alone so deletions of tracked Resources still
+		// trigger a re-read.
+		return !r.reader().matchesGlob(base)
+	}
+	if info.IsDir() {
+		// directory events themselves never carry Resources; addWatches
+		// handles picking up newly created subpackage directories.
+		return true
+	}
+	rr := r.reader()
+	matched, matchErr := (&rr).shouldSkipFile(event.Name, info)
+	if matchErr != nil {
+		return true
+	}
+	return !matched
+}
+
+// matchesGlob reports whether name matches one of MatchFilesGlob,
+// defaulting to LocalPackageReader's default globs if none are set.
+func (r LocalPackageReader) matchesGlob(name string) bool {
+	globs := r.MatchFilesGlob
+	if len(globs) == 0 {
+		globs = defaultMatch
+	}
+	for _, g := range globs {
+		if match, err := filepath.Match(g, name); err == nil && match {
+			return true
+		}
+	}
+	return false
+}
+
+// isTransientFile returns true for the swap/lock/temp files common
+// editors create alongside the file they're saving.
+func isTransientFile(base string) bool {
+	switch {
+	case strings.HasSuffix(base, "~"): // emacs/vi backup files
+		return true
+	case strings.HasSuffix(base, ".swp"), strings.HasSuffix(base, ".swx"): // vim swap files
+		return true
+	case strings.HasPrefix(base, ".#"): // emacs lock files
+		return true
+	case base == "4913": // vim's atomic-write permissions probe file
+		return true
+	case strings.HasSuffix(base, ".tmp"):
+		return true
+	}
+	return false
+}
@@ -0,0 +1,114 @@
+// Copyright 2019 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package kio
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+// nextSnapshot waits for either a snapshot or error from a Watcher, failing
+// the test if neither arrives before the timeout.
+func nextSnapshot(t *testing.T, nodesCh <-chan []*yaml.RNode, errCh <-chan error) []*yaml.RNode {
+	t.Helper()
+	select {
+	case nodes := <-nodesCh:
+		return nodes
+	case err := <-errCh:
+		require.NoError(t, err)
+		return nil
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for watch snapshot")
+		return nil
+	}
+}
+
+func TestWatcher_createModifyDelete(t *testing.T) {
+	dir, err := ioutil.TempDir("", "kyaml-watch")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	w := Watcher{PackagePath: dir, Debounce: 10 * time.Millisecond}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	nodesCh, errCh := w.Watch(ctx)
+
+	// create
+	f := filepath.Join(dir, "a.yaml")
+	require.NoError(t, ioutil.WriteFile(f, []byte("kind: Foo\n"), 0600))
+	nodes := nextSnapshot(t, nodesCh, errCh)
+	assert.Len(t, nodes, 1)
+
+	// modify
+	require.NoError(t, ioutil.WriteFile(f, []byte("kind: Bar\n"), 0600))
+	nodes = nextSnapshot(t, nodesCh, errCh)
+	require.Len(t, nodes, 1)
+	meta, err := nodes[0].GetMeta()
+	require.NoError(t, err)
+	assert.Equal(t, "Bar", meta.Kind)
+
+	// delete
+	require.NoError(t, os.Remove(f))
+	nodes = nextSnapshot(t, nodesCh, errCh)
+	assert.Len(t, nodes, 0)
+}
+
+func TestWatcher_subpackageAdded(t *testing.T) {
+	dir, err := ioutil.TempDir("", "kyaml-watch")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	w := Watcher{
+		PackagePath:        dir,
+		PackageFileName:    "Kptfile",
+		IncludeSubpackages: true,
+		Debounce:           10 * time.Millisecond,
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	nodesCh, errCh := w.Watch(ctx)
+
+	sub := filepath.Join(dir, "sub")
+	require.NoError(t, os.Mkdir(sub, 0700))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(sub, "Kptfile"), []byte("kind: Kptfile\n"), 0600))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(sub, "a.yaml"), []byte("kind: Foo\n"), 0600))
+
+	// only a.yaml is read: the Kptfile doesn't match the default globs.
+	nodes := nextSnapshot(t, nodesCh, errCh)
+	assert.Len(t, nodes, 1)
+}
+
+func TestWatcher_debounceCoalescesBurst(t *testing.T) {
+	dir, err := ioutil.TempDir("", "kyaml-watch")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	w := Watcher{PackagePath: dir, Debounce: 50 * time.Millisecond}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	nodesCh, errCh := w.Watch(ctx)
+
+	for i := 0; i < 5; i++ {
+		f := filepath.Join(dir, "a.yaml")
+		require.NoError(t, ioutil.WriteFile(f, []byte("kind: Foo\n"), 0600))
+	}
+
+	nodes := nextSnapshot(t, nodesCh, errCh)
+	assert.Len(t, nodes, 1)
+
+	select {
+	case extra := <-nodesCh:
+		t.Fatalf("expected burst of writes to coalesce into one snapshot, got extra: %v", extra)
+	case <-time.After(200 * time.Millisecond):
+		// no additional snapshot arrived -- the burst was coalesced.
+	}
+}
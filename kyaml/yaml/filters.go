@@ -8,9 +8,17 @@ import (
 	"regexp"
 	"sort"
 	"strings"
+	"sync"
 )
 
-var Filters = map[string]func() Filter{
+// FilterConstructor returns a new instance of the Filter for a YFilter Kind.
+type FilterConstructor func() Filter
+
+// Filters are the built-in Filter constructors, keyed by Kind, that
+// YFilter.UnmarshalYAML knows how to instantiate.  Kinds here can't be
+// replaced or removed -- use OverrideFilter if a built-in needs to be
+// shadowed.
+var Filters = map[string]FilterConstructor{
 	"AnnotationClearer": func() Filter { return &AnnotationClearer{} },
 	"AnnotationGetter":  func() Filter { return &AnnotationGetter{} },
 	"AnnotationSetter":  func() Filter { return &AnnotationSetter{} },
@@ -29,6 +37,81 @@ var Filters = map[string]func() Filter{
 	"TeePiper":          func() Filter { return &TeePiper{} },
 }
 
+var (
+	registeredFiltersMu sync.RWMutex
+	registeredFilters   = map[string]FilterConstructor{}
+)
+
+// RegisterFilter makes ctor available under kind to YFilter.UnmarshalYAML,
+// so that third-party modules can plug custom Filters into kyaml pipelines
+// without forking this package.  It is safe for concurrent use.
+//
+// RegisterFilter rejects kind if it's already taken by a built-in Filter
+// or a previous registration -- use OverrideFilter to replace one.
+func RegisterFilter(kind string, ctor FilterConstructor) error {
+	registeredFiltersMu.Lock()
+	defer registeredFiltersMu.Unlock()
+	if _, found := Filters[kind]; found {
+		return fmt.Errorf("filter Kind %s is already registered as a built-in filter", kind)
+	}
+	if _, found := registeredFilters[kind]; found {
+		return fmt.Errorf("filter Kind %s is already registered, use OverrideFilter to replace it", kind)
+	}
+	registeredFilters[kind] = ctor
+	return nil
+}
+
+// OverrideFilter registers ctor under kind, replacing any Filter -- built-in
+// or previously registered -- already using that Kind.
+func OverrideFilter(kind string, ctor FilterConstructor) {
+	registeredFiltersMu.Lock()
+	defer registeredFiltersMu.Unlock()
+	registeredFilters[kind] = ctor
+}
+
+// UnregisterFilter removes a Filter registered under kind by RegisterFilter
+// or OverrideFilter.  It has no effect on built-in Filters and is a no-op if
+// kind was never registered.
+func UnregisterFilter(kind string) {
+	registeredFiltersMu.Lock()
+	defer registeredFiltersMu.Unlock()
+	delete(registeredFilters, kind)
+}
+
+// LookupFilter returns the constructor for kind, checking Filters added
+// with RegisterFilter or OverrideFilter first so an override takes effect,
+// then falling back to the built-in Filters.
+func LookupFilter(kind string) (FilterConstructor, bool) {
+	registeredFiltersMu.RLock()
+	defer registeredFiltersMu.RUnlock()
+	if ctor, found := registeredFilters[kind]; found {
+		return ctor, true
+	}
+	ctor, found := Filters[kind]
+	return ctor, found
+}
+
+// RegisteredFilterKinds returns the sorted set of Kinds YFilter can
+// currently unmarshal, combining built-in Filters with any added via
+// RegisterFilter or OverrideFilter.
+func RegisteredFilterKinds() []string {
+	registeredFiltersMu.RLock()
+	defer registeredFiltersMu.RUnlock()
+	seen := map[string]bool{}
+	for k := range Filters {
+		seen[k] = true
+	}
+	for k := range registeredFilters {
+		seen[k] = true
+	}
+	kinds := make([]string, 0, len(seen))
+	for k := range seen {
+		kinds = append(kinds, k)
+	}
+	sort.Strings(kinds)
+	return kinds
+}
+
 // YFilter wraps the GrepFilter interface so it can be unmarshalled into a struct.
 type YFilter struct {
 	Filter
@@ -43,16 +126,11 @@ func (y *YFilter) UnmarshalYAML(unmarshal func(interface{}) error) error {
 	if err := unmarshal(meta); err != nil {
 		return err
 	}
-	if filter, found := Filters[meta.Kind]; !found {
-		var knownFilters []string
-		for k := range Filters {
-			knownFilters = append(knownFilters, k)
-		}
-		sort.Strings(knownFilters)
+	if ctor, found := LookupFilter(meta.Kind); !found {
 		return fmt.Errorf("unsupported GrepFilter Kind %s:  may be one of: [%s]",
-			meta.Kind, strings.Join(knownFilters, ","))
+			meta.Kind, strings.Join(RegisteredFilterKinds(), ","))
 	} else {
-		y.Filter = filter()
+		y.Filter = ctor()
 	}
 	if err := unmarshal(y.Filter); err != nil {
 		return err
@@ -0,0 +1,88 @@
+// Copyright 2019 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package yaml
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeFilter struct {
+	Kind string `yaml:"kind"`
+}
+
+func (fakeFilter) Filter(rn *RNode) (*RNode, error) { return rn, nil }
+
+func TestRegisterFilter(t *testing.T) {
+	defer UnregisterFilter("FakeFilter")
+
+	err := RegisterFilter("FakeFilter", func() Filter { return &fakeFilter{} })
+	require.NoError(t, err)
+
+	ctor, found := LookupFilter("FakeFilter")
+	require.True(t, found)
+	_, ok := ctor().(*fakeFilter)
+	assert.True(t, ok)
+
+	assert.Contains(t, RegisteredFilterKinds(), "FakeFilter")
+}
+
+func TestRegisterFilter_duplicateKind(t *testing.T) {
+	defer UnregisterFilter("FakeFilter")
+
+	require.NoError(t, RegisterFilter("FakeFilter", func() Filter { return &fakeFilter{} }))
+	err := RegisterFilter("FakeFilter", func() Filter { return &fakeFilter{} })
+	assert.Error(t, err)
+}
+
+func TestRegisterFilter_builtinKind(t *testing.T) {
+	err := RegisterFilter("PrefixSetter", func() Filter { return &fakeFilter{} })
+	assert.Error(t, err)
+}
+
+func TestOverrideFilter(t *testing.T) {
+	defer UnregisterFilter("FakeFilter")
+
+	require.NoError(t, RegisterFilter("FakeFilter", func() Filter { return &fakeFilter{} }))
+	OverrideFilter("FakeFilter", func() Filter { return &fakeFilter{Kind: "overridden"} })
+
+	ctor, found := LookupFilter("FakeFilter")
+	require.True(t, found)
+	f := ctor().(*fakeFilter)
+	assert.Equal(t, "overridden", f.Kind)
+}
+
+func TestOverrideFilter_builtinKind(t *testing.T) {
+	defer UnregisterFilter("PrefixSetter")
+
+	OverrideFilter("PrefixSetter", func() Filter { return &fakeFilter{Kind: "overridden"} })
+
+	ctor, found := LookupFilter("PrefixSetter")
+	require.True(t, found)
+	f, ok := ctor().(*fakeFilter)
+	require.True(t, ok)
+	assert.Equal(t, "overridden", f.Kind)
+
+	assert.Equal(t, 1, countOccurrences(RegisteredFilterKinds(), "PrefixSetter"))
+}
+
+func countOccurrences(s []string, v string) int {
+	n := 0
+	for _, e := range s {
+		if e == v {
+			n++
+		}
+	}
+	return n
+}
+
+func TestUnregisterFilter(t *testing.T) {
+	require.NoError(t, RegisterFilter("FakeFilter", func() Filter { return &fakeFilter{} }))
+	UnregisterFilter("FakeFilter")
+
+	_, found := LookupFilter("FakeFilter")
+	assert.False(t, found)
+}